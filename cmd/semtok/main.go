@@ -0,0 +1,58 @@
+// Copyright 2025 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command semtok prints a source file annotated with the semantic
+// tokens its language server reports for it.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cloudwego/abcoder/lang/lsp"
+)
+
+func main() {
+	lang := flag.String("lang", "go", "language of the file (go, rust, python, ...)")
+	root := flag.String("root", ".", "project root passed to the language server")
+	jsonOut := flag.Bool("json", false, "emit a JSON side-channel instead of inline comments")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: semtok [-lang go] [-root .] [-json] <file>")
+		os.Exit(2)
+	}
+	file := flag.Arg(0)
+
+	ctx := context.Background()
+	cli, err := lsp.NewLSPClient(ctx, lsp.Language(*lang), *root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "start language server:", err)
+		os.Exit(1)
+	}
+	defer cli.Close()
+
+	style := lsp.AnnotationComment
+	if *jsonOut {
+		style = lsp.AnnotationJSON
+	}
+	out, err := cli.AnnotateFile(ctx, lsp.NewDocumentURI(file), style)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "annotate file:", err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
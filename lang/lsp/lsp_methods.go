@@ -20,11 +20,15 @@ import (
 	"math"
 	"os"
 	"sort"
+	"sync"
 
 	"github.com/cloudwego/abcoder/lang/utils"
 	lsp "github.com/sourcegraph/go-lsp"
 )
 
+// defaultPreloadWorkers bounds PreloadSemanticTokens concurrency.
+const defaultPreloadWorkers = 8
+
 type DocumentRange struct {
 	TextDocument lsp.TextDocumentIdentifier `json:"textDocument"`
 	Range        Range                      `json:"range"`
@@ -34,24 +38,104 @@ type SemanticTokensFullParams struct {
 	TextDocument lsp.TextDocumentIdentifier `json:"textDocument"`
 }
 
+type SemanticTokensDeltaParams struct {
+	TextDocument     lsp.TextDocumentIdentifier `json:"textDocument"`
+	PreviousResultID string                     `json:"previousResultId"`
+}
+
+// SemanticTokensDelta covers both possible response shapes: a delta
+// (ResultID+Edits) or a full resend (ResultID+Data).
+type SemanticTokensDelta struct {
+	ResultID string               `json:"resultId"`
+	Edits    []SemanticTokensEdit `json:"edits,omitempty"`
+	Data     []uint32             `json:"data,omitempty"`
+}
+
+type SemanticTokensEdit struct {
+	Start       int      `json:"start"`
+	DeleteCount int      `json:"deleteCount"`
+	Data        []uint32 `json:"data,omitempty"`
+}
+
+// SemanticTokensOptions is the semanticTokensProvider capability entry.
+type SemanticTokensOptions struct {
+	Full  *SemanticTokensFullOptions `json:"full,omitempty"`
+	Range bool                       `json:"range,omitempty"`
+}
+
+type SemanticTokensFullOptions struct {
+	Delta bool `json:"delta,omitempty"`
+}
+
 type DidOpenTextDocumentParams struct {
 	TextDocument TextDocumentItem `json:"textDocument"`
 }
 
-func (cli *LSPClient) DidOpen(ctx context.Context, file DocumentURI) (*TextDocumentItem, error) {
+type DidChangeTextDocumentParams struct {
+	TextDocument   lsp.VersionedTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent    `json:"contentChanges"`
+}
+
+// TextDocumentContentChangeEvent describes a full-document replacement.
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument lsp.TextDocumentIdentifier `json:"textDocument"`
+}
+
+// MarkupContent mirrors the LSP MarkupContent structure; go-lsp predates it.
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type HoverResult struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+type SignatureHelpResult struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature int                    `json:"activeSignature"`
+	ActiveParameter int                    `json:"activeParameter"`
+}
+
+type SignatureInformation struct {
+	Label         string                 `json:"label"`
+	Documentation *MarkupContent         `json:"documentation,omitempty"`
+	Parameters    []ParameterInformation `json:"parameters,omitempty"`
+}
+
+type ParameterInformation struct {
+	Label         string         `json:"label"`
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+}
+
+// DidOpen opens file with the language server, caching its contents. If
+// content is given, it is used as the document's text instead of
+// reading file from disk.
+func (cli *LSPClient) DidOpen(ctx context.Context, file DocumentURI, content ...string) (*TextDocumentItem, error) {
 	if f, ok := cli.files[file]; ok {
 		return f, nil
 	}
-	text, err := os.ReadFile(file.File())
-	if err != nil {
-		return nil, err
+	var text string
+	if len(content) > 0 {
+		text = content[0]
+	} else {
+		raw, err := os.ReadFile(file.File())
+		if err != nil {
+			return nil, err
+		}
+		text = string(raw)
 	}
 	f := &TextDocumentItem{
 		URI:        DocumentURI(file),
 		LanguageID: cli.Language.String(),
 		Version:    1,
-		Text:       string(text),
-		LineCounts: utils.CountLines(string(text)),
+		Text:       text,
+		LineCounts: utils.CountLines(text),
 	}
 	cli.files[file] = f
 	req := DidOpenTextDocumentParams{
@@ -63,6 +147,45 @@ func (cli *LSPClient) DidOpen(ctx context.Context, file DocumentURI) (*TextDocum
 	return f, nil
 }
 
+// DidChange notifies the language server that file's contents changed
+// to newText, bumping its version and invalidating derived caches. The
+// semantic-tokens-delta resultId and raw stream are kept so the next
+// semanticTokensFull can request a delta instead of a full refetch.
+func (cli *LSPClient) DidChange(ctx context.Context, file DocumentURI, newText string) error {
+	f, ok := cli.files[file]
+	if !ok {
+		_, err := cli.DidOpen(ctx, file, newText)
+		return err
+	}
+	f.Version++
+	f.Text = newText
+	f.LineCounts = utils.CountLines(newText)
+	f.Symbols = nil
+	f.Tokens = nil
+	f.Definitions = nil
+	f.Implementations = nil
+	f.Hovers = nil
+	f.SignatureHelps = nil
+
+	req := DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: lsp.DocumentURI(file)},
+			Version:                f.Version,
+		},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: newText}},
+	}
+	return cli.Notify(ctx, "textDocument/didChange", req)
+}
+
+// DidClose notifies the server that file is closed and drops its cache.
+func (cli *LSPClient) DidClose(ctx context.Context, file DocumentURI) error {
+	delete(cli.files, file)
+	req := DidCloseTextDocumentParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: lsp.DocumentURI(file)},
+	}
+	return cli.Notify(ctx, "textDocument/didClose", req)
+}
+
 func (cli *LSPClient) DocumentSymbols(ctx context.Context, file DocumentURI) (map[Range]*DocumentSymbol, error) {
 	// open file first
 	f, err := cli.DidOpen(ctx, file)
@@ -155,16 +278,16 @@ func (cli *LSPClient) semanticTokensRange(ctx context.Context, id Location, sym
 func (cli *LSPClient) semanticTokensFull(ctx context.Context, id Location, sym *DocumentSymbol) ([]Token, error) {
 	f, ok := cli.files[id.URI]
 	if !ok || f.Tokens == nil {
-		req := SemanticTokensFullParams{
-			TextDocument: lsp.TextDocumentIdentifier{
-				URI: lsp.DocumentURI(id.URI),
-			},
+		if ok && f.TokensResultID != "" && cli.supportsSemanticTokensDelta() {
+			toks, err := cli.semanticTokensFullDelta(ctx, id, f, sym)
+			if err != nil {
+				return nil, err
+			}
+			return toks, nil
 		}
-		var resp SemanticTokens
-		if err := cli.Call(ctx, "textDocument/semanticTokens/full", req, &resp); err != nil {
+		if err := cli.fetchSemanticTokensFull(ctx, id, f); err != nil {
 			return nil, err
 		}
-		f.Tokens = cli.parseTokens(resp, id.URI)
 	}
 	toks := cli.filterTokens(f.Tokens, id.Range)
 	if sym != nil {
@@ -173,6 +296,89 @@ func (cli *LSPClient) semanticTokensFull(ctx context.Context, id Location, sym *
 	return toks, nil
 }
 
+// supportsSemanticTokensDelta reports server support for full/delta.
+func (cli *LSPClient) supportsSemanticTokensDelta() bool {
+	full := cli.capabilities.SemanticTokensProvider
+	return full != nil && full.Full != nil && full.Full.Delta
+}
+
+// fetchSemanticTokensFull fetches id's whole-file token stream and
+// refreshes f's cache from it.
+func (cli *LSPClient) fetchSemanticTokensFull(ctx context.Context, id Location, f *TextDocumentItem) error {
+	req := SemanticTokensFullParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(id.URI),
+		},
+	}
+	var resp SemanticTokens
+	if err := cli.Call(ctx, "textDocument/semanticTokens/full", req, &resp); err != nil {
+		return err
+	}
+	f.TokensRaw = resp.Data
+	f.TokensResultID = resp.ResultID
+	f.Tokens = cli.parseTokens(resp, id.URI)
+	return nil
+}
+
+// semanticTokensFullDelta refreshes f's token cache via full/delta
+// instead of refetching the entire token stream. If the server's edits
+// don't fit the cached stream, it falls back to fetchSemanticTokensFull
+// rather than splicing malformed data.
+func (cli *LSPClient) semanticTokensFullDelta(ctx context.Context, id Location, f *TextDocumentItem, sym *DocumentSymbol) ([]Token, error) {
+	req := SemanticTokensDeltaParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(id.URI),
+		},
+		PreviousResultID: f.TokensResultID,
+	}
+	var resp SemanticTokensDelta
+	if err := cli.Call(ctx, "textDocument/semanticTokens/full/delta", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Edits == nil {
+		// server sent a full replacement rather than a delta
+		f.TokensRaw = resp.Data
+		f.TokensResultID = resp.ResultID
+	} else {
+		data, err := applySemanticTokenEdits(f.TokensRaw, resp.Edits)
+		if err != nil {
+			// malformed delta: abandon it and fetch the whole stream fresh
+			if err := cli.fetchSemanticTokensFull(ctx, id, f); err != nil {
+				return nil, err
+			}
+			toks := cli.filterTokens(f.Tokens, id.Range)
+			if sym != nil {
+				sym.Tokens = toks
+			}
+			return toks, nil
+		}
+		f.TokensRaw = data
+		f.TokensResultID = resp.ResultID
+	}
+	f.Tokens = cli.parseTokens(SemanticTokens{Data: f.TokensRaw, ResultID: f.TokensResultID}, id.URI)
+
+	toks := cli.filterTokens(f.Tokens, id.Range)
+	if sym != nil {
+		sym.Tokens = toks
+	}
+	return toks, nil
+}
+
+// applySemanticTokenEdits splices edits into a cached raw token stream, in
+// ascending Start order. It errors rather than panicking if an edit doesn't
+// fit the stream, so the caller can fall back to a fresh full fetch.
+func applySemanticTokenEdits(data []uint32, edits []SemanticTokensEdit) ([]uint32, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+	for _, e := range edits {
+		if e.Start < 0 || e.Start+e.DeleteCount > len(data) {
+			return nil, fmt.Errorf("semantic token edit out of bounds: start=%d deleteCount=%d len=%d", e.Start, e.DeleteCount, len(data))
+		}
+		tail := append([]uint32{}, data[e.Start+e.DeleteCount:]...)
+		data = append(append(data[:e.Start:e.Start], e.Data...), tail...)
+	}
+	return data, nil
+}
+
 func (cli *LSPClient) Definition(ctx context.Context, uri DocumentURI, pos Position) ([]Location, error) {
 	// open file first
 	f, err := cli.DidOpen(ctx, uri)
@@ -219,6 +425,100 @@ func (cli *LSPClient) TypeDefinition(ctx context.Context, uri DocumentURI, pos P
 	return resp, nil
 }
 
+func (cli *LSPClient) Implementation(ctx context.Context, uri DocumentURI, pos Position) ([]Location, error) {
+	f, err := cli.DidOpen(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if f.Implementations != nil {
+		if locations, ok := f.Implementations[pos]; ok {
+			return locations, nil
+		}
+	}
+
+	req := lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(uri),
+		},
+		Position: lsp.Position(pos),
+	}
+	var resp []Location
+	if err := cli.Call(ctx, "textDocument/implementation", req, &resp); err != nil {
+		return nil, err
+	}
+
+	if f.Implementations == nil {
+		f.Implementations = make(map[Position][]Location)
+	}
+	f.Implementations[pos] = resp
+	return resp, nil
+}
+
+func (cli *LSPClient) Hover(ctx context.Context, uri DocumentURI, pos Position) (MarkupContent, Range, error) {
+	f, err := cli.DidOpen(ctx, uri)
+	if err != nil {
+		return MarkupContent{}, Range{}, err
+	}
+	if f.Hovers != nil {
+		if h, ok := f.Hovers[pos]; ok {
+			return h.Contents, hoverRange(h), nil
+		}
+	}
+
+	req := lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(uri),
+		},
+		Position: lsp.Position(pos),
+	}
+	var resp HoverResult
+	if err := cli.Call(ctx, "textDocument/hover", req, &resp); err != nil {
+		return MarkupContent{}, Range{}, err
+	}
+
+	if f.Hovers == nil {
+		f.Hovers = make(map[Position]*HoverResult)
+	}
+	f.Hovers[pos] = &resp
+	return resp.Contents, hoverRange(&resp), nil
+}
+
+func hoverRange(h *HoverResult) Range {
+	if h.Range == nil {
+		return Range{}
+	}
+	return *h.Range
+}
+
+func (cli *LSPClient) SignatureHelp(ctx context.Context, uri DocumentURI, pos Position) (SignatureHelpResult, error) {
+	f, err := cli.DidOpen(ctx, uri)
+	if err != nil {
+		return SignatureHelpResult{}, err
+	}
+	if f.SignatureHelps != nil {
+		if s, ok := f.SignatureHelps[pos]; ok {
+			return *s, nil
+		}
+	}
+
+	req := lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(uri),
+		},
+		Position: lsp.Position(pos),
+	}
+	var resp SignatureHelpResult
+	if err := cli.Call(ctx, "textDocument/signatureHelp", req, &resp); err != nil {
+		return SignatureHelpResult{}, err
+	}
+
+	if f.SignatureHelps == nil {
+		f.SignatureHelps = make(map[Position]*SignatureHelpResult)
+	}
+	f.SignatureHelps[pos] = &resp
+	return resp, nil
+}
+
 // read file and get the text of block of range
 func (cli *LSPClient) Locate(id Location) (string, error) {
 	f, ok := cli.files[id.URI]
@@ -423,6 +723,64 @@ func (cli *LSPClient) FileStructure(ctx context.Context, file DocumentURI) ([]*D
 	return constructSymbolHierarchy(symbols), nil
 }
 
+// PreloadSemanticTokens fetches semantic tokens for every DocumentSymbol
+// in file up front, populating DocumentSymbol.Tokens so later
+// SemanticTokens calls are cache hits.
+func (cli *LSPClient) PreloadSemanticTokens(ctx context.Context, file DocumentURI, workers ...int) error {
+	syms, err := cli.DocumentSymbols(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	if !cli.hasSemanticTokensRange {
+		full := Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: math.MaxInt32, Character: math.MaxInt32}}
+		if _, err := cli.semanticTokensFull(ctx, Location{URI: file, Range: full}, nil); err != nil {
+			return err
+		}
+		f := cli.files[file]
+		for _, sym := range syms {
+			sym.Tokens = cli.filterTokens(f.Tokens, sym.Location.Range)
+		}
+		return nil
+	}
+
+	n := defaultPreloadWorkers
+	if len(workers) > 0 && workers[0] > 0 {
+		n = workers[0]
+	}
+
+	jobs := make(chan *DocumentSymbol)
+	errs := make(chan error, len(syms))
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sym := range jobs {
+				if sym.Tokens != nil {
+					continue
+				}
+				if _, err := cli.semanticTokensRange(ctx, Location{URI: file, Range: sym.Location.Range}, sym); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for _, sym := range syms {
+		jobs <- sym
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getSemanticTokenType(id int, semanticTokenTypes []string) string {
 	if id < len(semanticTokenTypes) {
 		return semanticTokenTypes[id]
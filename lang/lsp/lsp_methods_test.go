@@ -0,0 +1,213 @@
+// Copyright 2025 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestPreloadSemanticTokensFullFallback covers the !hasSemanticTokensRange
+// path: a single whole-file fetch followed by per-symbol slicing, with the
+// file's tokens already cached so no network call is made.
+func TestPreloadSemanticTokensFullFallback(t *testing.T) {
+	file := DocumentURI("file:///preload.go")
+	f := &TextDocumentItem{URI: file, Text: "ab\ncd\nef\n", LineCounts: []int{0, 3, 6}}
+	cli := &LSPClient{
+		files:          map[DocumentURI]*TextDocumentItem{file: f},
+		tokenTypes:     []string{"keyword", "identifier"},
+		tokenModifiers: []string{"declaration"},
+	}
+	raw := SemanticTokens{Data: []uint32{
+		0, 0, 2, 1, 1,
+		1, 0, 2, 0, 2,
+		1, 0, 2, 1, 0,
+	}}
+	f.Tokens = cli.parseTokens(raw, file)
+	f.Symbols = map[Range]*DocumentSymbol{
+		tokenRange(0, 0, 0, 2): {Location: Location{URI: file, Range: tokenRange(0, 0, 0, 2)}},
+		tokenRange(1, 0, 1, 2): {Location: Location{URI: file, Range: tokenRange(1, 0, 1, 2)}},
+		tokenRange(2, 0, 2, 2): {Location: Location{URI: file, Range: tokenRange(2, 0, 2, 2)}},
+	}
+
+	if err := cli.PreloadSemanticTokens(context.Background(), file); err != nil {
+		t.Fatalf("PreloadSemanticTokens() error = %v", err)
+	}
+
+	for r, sym := range f.Symbols {
+		if len(sym.Tokens) != 1 {
+			t.Fatalf("symbol %+v got %d tokens, want 1", r, len(sym.Tokens))
+		}
+		if sym.Tokens[0].Location.Range != r {
+			t.Fatalf("symbol %+v got token for range %+v", r, sym.Tokens[0].Location.Range)
+		}
+	}
+}
+
+// TestPreloadSemanticTokensRangeCapableSkipsCached covers the
+// hasSemanticTokensRange worker-pool path: symbols whose Tokens are
+// already cached must be left untouched and must not trigger cli.Call.
+func TestPreloadSemanticTokensRangeCapableSkipsCached(t *testing.T) {
+	file := DocumentURI("file:///preload_range.go")
+	f := &TextDocumentItem{URI: file, Text: "ab\ncd\n", LineCounts: []int{0, 3}}
+	sym1 := &DocumentSymbol{Location: Location{URI: file, Range: tokenRange(0, 0, 0, 2)}, Tokens: []Token{{Type: "identifier"}}}
+	sym2 := &DocumentSymbol{Location: Location{URI: file, Range: tokenRange(1, 0, 1, 2)}, Tokens: []Token{{Type: "keyword"}}}
+	f.Symbols = map[Range]*DocumentSymbol{
+		sym1.Location.Range: sym1,
+		sym2.Location.Range: sym2,
+	}
+	cli := &LSPClient{
+		files:                  map[DocumentURI]*TextDocumentItem{file: f},
+		hasSemanticTokensRange: true,
+	}
+
+	if err := cli.PreloadSemanticTokens(context.Background(), file, 2); err != nil {
+		t.Fatalf("PreloadSemanticTokens() error = %v", err)
+	}
+	if sym1.Tokens[0].Type != "identifier" || sym2.Tokens[0].Type != "keyword" {
+		t.Fatalf("preload mutated already-cached symbol tokens unexpectedly: sym1=%+v sym2=%+v", sym1.Tokens, sym2.Tokens)
+	}
+}
+
+// TestPreloadSemanticTokensDocumentSymbolsError covers the early-return
+// when the initial DocumentSymbols lookup fails.
+func TestPreloadSemanticTokensDocumentSymbolsError(t *testing.T) {
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{}}
+
+	if err := cli.PreloadSemanticTokens(context.Background(), DocumentURI("file:///does/not/exist.go")); err == nil {
+		t.Fatal("PreloadSemanticTokens() error = nil, want error for unopenable file")
+	}
+}
+
+// TestDidChangeInvalidatesDerivedCachesButKeepsDeltaState guards against a
+// regression where DidChange cleared TokensResultID/TokensRaw along with
+// the rest of the derived caches, making the semanticTokens/full/delta
+// path permanently unreachable.
+func TestDidChangeInvalidatesDerivedCachesButKeepsDeltaState(t *testing.T) {
+	file := DocumentURI("file:///didchange.go")
+	f := &TextDocumentItem{
+		URI:             file,
+		Text:            "old",
+		LineCounts:      []int{0},
+		Symbols:         map[Range]*DocumentSymbol{tokenRange(0, 0, 0, 1): {}},
+		Tokens:          []Token{{Type: "x"}},
+		TokensRaw:       []uint32{0, 0, 1, 0, 0},
+		TokensResultID:  "r1",
+		Definitions:     map[Position][]Location{{Line: 0, Character: 0}: {{}}},
+		Implementations: map[Position][]Location{{Line: 0, Character: 0}: {{}}},
+		Hovers:          map[Position]*HoverResult{{Line: 0, Character: 0}: {}},
+		SignatureHelps:  map[Position]*SignatureHelpResult{{Line: 0, Character: 0}: {}},
+	}
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: f}}
+
+	// DidChange always notifies the server; with no real transport behind
+	// cli.Notify in this test, it may return an error, but the cache
+	// mutations below run unconditionally before that call.
+	_ = cli.DidChange(context.Background(), file, "new")
+
+	if f.Text != "new" {
+		t.Fatalf("DidChange did not update Text, got %q", f.Text)
+	}
+	if f.Symbols != nil || f.Tokens != nil || f.Definitions != nil || f.Implementations != nil || f.Hovers != nil || f.SignatureHelps != nil {
+		t.Fatal("DidChange did not invalidate derived caches")
+	}
+	if f.TokensResultID != "r1" {
+		t.Fatalf("DidChange cleared TokensResultID, want it retained for delta reuse, got %q", f.TokensResultID)
+	}
+	if len(f.TokensRaw) != 5 {
+		t.Fatalf("DidChange cleared TokensRaw, want it retained, got %v", f.TokensRaw)
+	}
+}
+
+// TestDidCloseDropsCache guards the cache-eviction half of DidClose.
+func TestDidCloseDropsCache(t *testing.T) {
+	file := DocumentURI("file:///didclose.go")
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: {URI: file}}}
+
+	_ = cli.DidClose(context.Background(), file)
+
+	if _, ok := cli.files[file]; ok {
+		t.Fatal("DidClose did not remove file from cache")
+	}
+}
+
+// TestHoverCacheHit proves a cached position is served directly, without
+// reaching cli.Call.
+func TestHoverCacheHit(t *testing.T) {
+	file := DocumentURI("file:///hover.go")
+	pos := Position{Line: 0, Character: 1}
+	want := &HoverResult{Contents: MarkupContent{Kind: "markdown", Value: "cached"}}
+	f := &TextDocumentItem{URI: file, Hovers: map[Position]*HoverResult{pos: want}}
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: f}}
+
+	contents, rng, err := cli.Hover(context.Background(), file, pos)
+	if err != nil {
+		t.Fatalf("Hover() error = %v", err)
+	}
+	if contents != want.Contents {
+		t.Fatalf("Hover() contents = %+v, want %+v", contents, want.Contents)
+	}
+	if rng != (Range{}) {
+		t.Fatalf("Hover() range = %+v, want zero value", rng)
+	}
+}
+
+// TestSignatureHelpCacheHit proves a cached position is served directly,
+// without reaching cli.Call.
+func TestSignatureHelpCacheHit(t *testing.T) {
+	file := DocumentURI("file:///sighelp.go")
+	pos := Position{Line: 2, Character: 4}
+	want := &SignatureHelpResult{ActiveSignature: 1, ActiveParameter: 2}
+	f := &TextDocumentItem{URI: file, SignatureHelps: map[Position]*SignatureHelpResult{pos: want}}
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: f}}
+
+	got, err := cli.SignatureHelp(context.Background(), file, pos)
+	if err != nil {
+		t.Fatalf("SignatureHelp() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, *want) {
+		t.Fatalf("SignatureHelp() = %+v, want %+v", got, *want)
+	}
+}
+
+// TestImplementationCacheHit proves a cached position is served directly,
+// without reaching cli.Call.
+func TestImplementationCacheHit(t *testing.T) {
+	file := DocumentURI("file:///impl.go")
+	pos := Position{Line: 3, Character: 5}
+	want := []Location{{URI: file, Range: tokenRange(10, 0, 10, 3)}}
+	f := &TextDocumentItem{URI: file, Implementations: map[Position][]Location{pos: want}}
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: f}}
+
+	got, err := cli.Implementation(context.Background(), file, pos)
+	if err != nil {
+		t.Fatalf("Implementation() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Implementation() = %+v, want %+v", got, want)
+	}
+}
+
+// TestImplementationOpenError proves DidOpen failures (e.g. an unopenable
+// file) surface as errors without populating the Implementations cache.
+func TestImplementationOpenError(t *testing.T) {
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{}}
+
+	_, err := cli.Implementation(context.Background(), DocumentURI("file:///does/not/exist.go"), Position{})
+	if err == nil {
+		t.Fatal("Implementation() error = nil, want error for unopenable file")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AnnotationStyle selects how AnnotateFile renders semantic tokens.
+type AnnotationStyle int
+
+const (
+	// AnnotationComment inlines a block comment after each token.
+	AnnotationComment AnnotationStyle = iota
+	// AnnotationJSON emits a side-channel JSON array instead.
+	AnnotationJSON
+)
+
+// TokenAnnotation is the JSON record emitted by AnnotationJSON.
+type TokenAnnotation struct {
+	Line      int      `json:"line"`
+	Char      int      `json:"char"`
+	Len       int      `json:"len"`
+	Type      string   `json:"type"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	Text      string   `json:"text"`
+}
+
+// AnnotateFile renders file's semantic tokens back onto its source, as
+// inline comments or as a side-channel JSON array depending on style.
+func (cli *LSPClient) AnnotateFile(ctx context.Context, file DocumentURI, style AnnotationStyle) (string, error) {
+	f, err := cli.DidOpen(ctx, file)
+	if err != nil {
+		return "", err
+	}
+	toks, err := cli.SemanticTokens(ctx, Location{URI: file, Range: Range{
+		Start: Position{Line: 0, Character: 0},
+		End:   Position{Line: len(f.LineCounts), Character: 0},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	if style == AnnotationJSON {
+		annos := make([]TokenAnnotation, 0, len(toks))
+		for _, t := range toks {
+			annos = append(annos, TokenAnnotation{
+				Line:      t.Location.Range.Start.Line,
+				Char:      t.Location.Range.Start.Character,
+				Len:       t.Location.Range.End.Character - t.Location.Range.Start.Character,
+				Type:      t.Type,
+				Modifiers: t.Modifiers,
+				Text:      t.Text,
+			})
+		}
+		out, err := json.MarshalIndent(annos, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, t := range toks {
+		end := f.LineCounts[t.Location.Range.End.Line] + t.Location.Range.End.Character
+		start := f.LineCounts[t.Location.Range.Start.Line] + t.Location.Range.Start.Character
+		if start < last {
+			// overlapping token, already covered by a preceding annotation
+			continue
+		}
+		b.WriteString(f.Text[last:end])
+		fmt.Fprintf(&b, "/*«%s,%s»*/", t.Type, strings.Join(t.Modifiers, ","))
+		last = end
+	}
+	b.WriteString(f.Text[last:])
+	return b.String(), nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func tokenRange(startLine, startChar, endLine, endChar int) Range {
+	return Range{
+		Start: Position{Line: startLine, Character: startChar},
+		End:   Position{Line: endLine, Character: endChar},
+	}
+}
+
+func TestParseTokensRange(t *testing.T) {
+	const text = "ab\ncd\nef\n"
+	file := DocumentURI("file:///test.go")
+	cli := &LSPClient{
+		files: map[DocumentURI]*TextDocumentItem{
+			file: {URI: file, Text: text, LineCounts: []int{0, 3, 6}},
+		},
+		tokenTypes:     []string{"keyword", "identifier"},
+		tokenModifiers: []string{"declaration", "readonly"},
+	}
+
+	raw := SemanticTokens{Data: []uint32{
+		0, 0, 2, 1, 1, // line 0, char 0, len 2, type "identifier", mods "declaration"
+		1, 0, 2, 0, 2, // line 1, char 0, len 2, type "keyword", mods "readonly"
+	}}
+
+	got := cli.parseTokens(raw, file)
+	want := []Token{
+		{Location: Location{URI: file, Range: tokenRange(0, 0, 0, 2)}, Type: "identifier", Modifiers: []string{"declaration"}, Text: "ab"},
+		{Location: Location{URI: file, Range: tokenRange(1, 0, 1, 2)}, Type: "keyword", Modifiers: []string{"readonly"}, Text: "cd"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseTokens() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFilterTokens(t *testing.T) {
+	tokens := []Token{
+		{Location: Location{Range: tokenRange(0, 0, 0, 2)}, Type: "identifier"},
+		{Location: Location{Range: tokenRange(1, 0, 1, 2)}, Type: "keyword"},
+		{Location: Location{Range: tokenRange(2, 0, 2, 2)}, Type: "identifier"},
+	}
+	cli := &LSPClient{}
+
+	got := cli.filterTokens(tokens, tokenRange(1, 0, 2, 0))
+	if len(got) != 1 || got[0].Type != "keyword" {
+		t.Fatalf("filterTokens() = %+v, want just the line-1 token", got)
+	}
+
+	if got := cli.filterTokens(nil, tokenRange(0, 0, 0, 0)); len(got) != 0 {
+		t.Fatalf("filterTokens(nil) = %+v, want empty", got)
+	}
+}
+
+func TestApplySemanticTokenEdits(t *testing.T) {
+	// two tokens worth of raw data: [0,0,2,1,1] [1,0,2,0,2]
+	data := []uint32{0, 0, 2, 1, 1, 1, 0, 2, 0, 2}
+	edits := []SemanticTokensEdit{
+		// replace the second token's tuple with a different one
+		{Start: 5, DeleteCount: 5, Data: []uint32{1, 0, 3, 0, 0}},
+	}
+
+	got, err := applySemanticTokenEdits(data, edits)
+	if err != nil {
+		t.Fatalf("applySemanticTokenEdits() error = %v", err)
+	}
+	want := []uint32{0, 0, 2, 1, 1, 1, 0, 3, 0, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("applySemanticTokenEdits() = %v, want %v", got, want)
+	}
+}
+
+func TestApplySemanticTokenEditsOutOfOrder(t *testing.T) {
+	// three tokens; edits arrive out of ascending-Start order and must
+	// still apply as if sorted.
+	data := []uint32{0, 0, 2, 1, 1, 1, 0, 2, 0, 2, 1, 0, 2, 1, 0}
+	edits := []SemanticTokensEdit{
+		{Start: 10, DeleteCount: 5, Data: []uint32{1, 0, 1, 0, 0}},
+		{Start: 0, DeleteCount: 5, Data: []uint32{0, 0, 1, 1, 1}},
+	}
+
+	got, err := applySemanticTokenEdits(data, edits)
+	if err != nil {
+		t.Fatalf("applySemanticTokenEdits() error = %v", err)
+	}
+	want := []uint32{0, 0, 1, 1, 1, 1, 0, 2, 0, 2, 1, 0, 1, 0, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("applySemanticTokenEdits() = %v, want %v", got, want)
+	}
+}
+
+func TestApplySemanticTokenEditsOutOfBounds(t *testing.T) {
+	data := []uint32{0, 0, 2, 1, 1}
+	edits := []SemanticTokensEdit{
+		// DeleteCount reaches past the end of data
+		{Start: 3, DeleteCount: 5, Data: []uint32{0, 0, 1, 0, 0}},
+	}
+
+	if _, err := applySemanticTokenEdits(data, edits); err == nil {
+		t.Fatal("applySemanticTokenEdits() error = nil, want error for out-of-bounds edit")
+	}
+}
+
+// annotateFileFixture builds an LSPClient whose file/symbol/token caches
+// are pre-populated, so AnnotateFile resolves entirely from cache and
+// never reaches cli.Call/cli.Notify.
+func annotateFileFixture() (*LSPClient, DocumentURI) {
+	const text = "ab\ncd\n"
+	file := DocumentURI("file:///annotate.go")
+	lineCounts := []int{0, 3}
+	full := Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: len(lineCounts), Character: 0}}
+	toks := []Token{
+		{Location: Location{URI: file, Range: tokenRange(0, 0, 0, 2)}, Type: "identifier", Modifiers: []string{"declaration"}, Text: "ab"},
+		{Location: Location{URI: file, Range: tokenRange(1, 0, 1, 2)}, Type: "keyword", Text: "cd"},
+	}
+	f := &TextDocumentItem{
+		URI:        file,
+		Text:       text,
+		LineCounts: lineCounts,
+		Symbols: map[Range]*DocumentSymbol{
+			full: {Location: Location{URI: file, Range: full}, Tokens: toks},
+		},
+	}
+	cli := &LSPClient{files: map[DocumentURI]*TextDocumentItem{file: f}}
+	return cli, file
+}
+
+func TestAnnotateFileComment(t *testing.T) {
+	cli, file := annotateFileFixture()
+
+	got, err := cli.AnnotateFile(context.Background(), file, AnnotationComment)
+	if err != nil {
+		t.Fatalf("AnnotateFile() error = %v", err)
+	}
+	want := "ab/*«identifier,declaration»*/\ncd/*«keyword,»*/\n"
+	if got != want {
+		t.Fatalf("AnnotateFile() = %q, want %q", got, want)
+	}
+}
+
+func TestAnnotateFileJSON(t *testing.T) {
+	cli, file := annotateFileFixture()
+
+	got, err := cli.AnnotateFile(context.Background(), file, AnnotationJSON)
+	if err != nil {
+		t.Fatalf("AnnotateFile() error = %v", err)
+	}
+	var annos []TokenAnnotation
+	if err := json.Unmarshal([]byte(got), &annos); err != nil {
+		t.Fatalf("AnnotateFile() produced invalid JSON: %v\noutput: %s", err, got)
+	}
+	want := []TokenAnnotation{
+		{Line: 0, Char: 0, Len: 2, Type: "identifier", Modifiers: []string{"declaration"}, Text: "ab"},
+		{Line: 1, Char: 0, Len: 2, Type: "keyword", Text: "cd"},
+	}
+	if !reflect.DeepEqual(annos, want) {
+		t.Fatalf("AnnotateFile() annotations = %+v, want %+v", annos, want)
+	}
+}
+
+func TestConstructSymbolHierarchy(t *testing.T) {
+	outer := &DocumentSymbol{Location: Location{Range: tokenRange(0, 0, 10, 0)}}
+	inner := &DocumentSymbol{Location: Location{Range: tokenRange(1, 0, 2, 0)}}
+	sibling := &DocumentSymbol{Location: Location{Range: tokenRange(20, 0, 21, 0)}}
+
+	roots := constructSymbolHierarchy([]*DocumentSymbol{sibling, inner, outer})
+
+	if len(roots) != 2 {
+		t.Fatalf("constructSymbolHierarchy() returned %d roots, want 2", len(roots))
+	}
+	if roots[0] != outer || len(outer.Children) != 1 || outer.Children[0] != inner {
+		t.Fatalf("expected inner to nest under outer, got roots=%+v outer.Children=%+v", roots, outer.Children)
+	}
+	if roots[1] != sibling {
+		t.Fatalf("expected sibling to stay a root, got %+v", roots[1])
+	}
+}